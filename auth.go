@@ -0,0 +1,198 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Authenticator attaches credentials to an outgoing request.
+type Authenticator interface {
+	Apply(req *http.Request)
+}
+
+// NoAuth is the zero-value Authenticator: it leaves the request untouched.
+type NoAuth struct{}
+
+func (NoAuth) Apply(req *http.Request) {}
+
+// BasicAuth sets HTTP Basic auth credentials.
+type BasicAuth struct {
+	User string
+	Pass string
+}
+
+func (a BasicAuth) Apply(req *http.Request) {
+	req.SetBasicAuth(a.User, a.Pass)
+}
+
+// BearerAuth sets an "Authorization: Bearer <token>" header.
+type BearerAuth struct {
+	Token string
+}
+
+func (a BearerAuth) Apply(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+}
+
+// APIKeyAuth sets an arbitrary header, e.g. "X-API-Key".
+type APIKeyAuth struct {
+	Header string
+	Value  string
+}
+
+func (a APIKeyAuth) Apply(req *http.Request) {
+	if a.Header == "" {
+		return
+	}
+	req.Header.Set(a.Header, a.Value)
+}
+
+// StoredCredential is the on-disk representation of one host's saved auth.
+type StoredCredential struct {
+	Scheme string `json:"scheme"` // "none", "basic", "bearer", or "apikey"
+	User   string `json:"user,omitempty"`
+	Pass   string `json:"pass,omitempty"`
+	Token  string `json:"token,omitempty"`
+	Header string `json:"header,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+// authenticatorFromStored builds the Authenticator a StoredCredential describes.
+func authenticatorFromStored(c StoredCredential) Authenticator {
+	switch c.Scheme {
+	case "basic":
+		return BasicAuth{User: c.User, Pass: c.Pass}
+	case "bearer":
+		return BearerAuth{Token: c.Token}
+	case "apikey":
+		return APIKeyAuth{Header: c.Header, Value: c.Value}
+	default:
+		return NoAuth{}
+	}
+}
+
+// hostFor extracts the host used as the credentials map key.
+func hostFor(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
+}
+
+// authPath returns ~/.config/go-forth/auth.json (or the platform equivalent).
+func authPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-forth", "auth.json"), nil
+}
+
+// loadCredentials reads the saved credentials file, decrypting it with
+// passphrase if non-empty. An empty passphrase is required for a file that
+// was saved unencrypted.
+func loadCredentials(passphrase string) (map[string]StoredCredential, error) {
+	path, err := authPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]StoredCredential{}, nil
+		}
+		return nil, err
+	}
+
+	if passphrase != "" {
+		data, err = decrypt(data, passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var creds map[string]StoredCredential
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// saveCredentials persists creds to disk, encrypting with passphrase when
+// it's non-empty.
+func saveCredentials(creds map[string]StoredCredential, passphrase string) error {
+	path, err := authPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if passphrase != "" {
+		data, err = encrypt(data, passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+// deriveKey turns a user passphrase into an AES-256 key.
+func deriveKey(passphrase string) [32]byte {
+	return sha256.Sum256([]byte(passphrase))
+}
+
+// encrypt seals plain with AES-256-GCM, prepending the nonce to the output.
+func encrypt(plain []byte, passphrase string) ([]byte, error) {
+	key := deriveKey(passphrase)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// decrypt reverses encrypt.
+func decrypt(data []byte, passphrase string) ([]byte, error) {
+	key := deriveKey(passphrase)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("credential file is corrupt or passphrase is wrong")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
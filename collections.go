@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// Collection is a saved request that can be reloaded into the form and
+// re-sent without retyping the URL, method, headers, and body.
+type Collection struct {
+	Name    string            `json:"name"`
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// collectionsPath returns ~/.config/go-forth/collections.json (or the
+// platform equivalent).
+func collectionsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-forth", "collections.json"), nil
+}
+
+// loadCollections reads the saved collections file, returning an empty slice
+// if it doesn't exist yet.
+func loadCollections() ([]Collection, error) {
+	path, err := collectionsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var collections []Collection
+	if err := json.Unmarshal(data, &collections); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+// saveCollections persists collections to disk, creating the config
+// directory if needed.
+func saveCollections(collections []Collection) error {
+	path, err := collectionsPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(collections, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// collectionItem adapts a Collection to the bubbles/list.Item interface.
+type collectionItem struct {
+	Collection
+}
+
+func (c collectionItem) Title() string       { return c.Name }
+func (c collectionItem) Description() string { return c.Method + " " + c.URL }
+func (c collectionItem) FilterValue() string { return c.Name }
+
+func collectionItems(collections []Collection) []list.Item {
+	items := make([]list.Item, len(collections))
+	for i, c := range collections {
+		items[i] = collectionItem{c}
+	}
+	return items
+}
+
+// headersToMap converts the editor's ordered header entries to the map shape
+// used by RequestSpec and Collection.
+func headersToMap(entries []headerEntry) map[string]string {
+	m := make(map[string]string, len(entries))
+	for _, h := range entries {
+		m[h.key] = h.value
+	}
+	return m
+}
+
+func headersFromMap(m map[string]string) []headerEntry {
+	entries := make([]headerEntry, 0, len(m))
+	for k, v := range m {
+		entries = append(entries, headerEntry{key: k, value: v})
+	}
+	return entries
+}
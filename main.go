@@ -2,19 +2,20 @@ package main
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
+	"reflect"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"golang.org/x/net/html"
 )
 
 // HTTP client configuration
@@ -43,15 +44,119 @@ type component int
 const (
 	textInputFocus component = iota
 	methodListFocus
+	headerFocus
+	bodyFocus
+	authUserFocus
+	authSecretFocus
+	passphraseFocus
+	collectionsFocus
+	viewportFocus
+	envNameFocus
+	envVarFocus
+	envListFocus
 )
 
+// focusOrder defines the Tab/Up/Down cycling order: URL -> method -> headers ->
+// body -> auth -> collections -> response.
+var focusOrder = []component{
+	textInputFocus, methodListFocus, headerFocus, bodyFocus,
+	authUserFocus, authSecretFocus, passphraseFocus,
+	collectionsFocus, viewportFocus,
+}
+
+// envFocusOrder defines the Tab cycling order within the environment editor
+// opened with ctrl+e: name -> variable entry -> saved environments. Up/Down do
+// not move focus here (see arrowCyclesFocus).
+var envFocusOrder = []component{envNameFocus, envVarFocus, envListFocus}
+
+// arrowCyclesFocus reports whether c is one of the two original single-line
+// fields that still cycle on the literal Up/Down arrow keys. Every other
+// focus state moves on with Tab only, so that its text widget (or, for
+// lists/the viewport, its own up/down navigation) can keep every
+// keystroke, including literal "j"/"k".
+func arrowCyclesFocus(c component) bool {
+	return c == textInputFocus || c == methodListFocus
+}
+
+// maxHistory bounds the in-memory ring of past requests recalled with ctrl+r.
+const maxHistory = 50
+
+// authSchemes defines the ctrl+a cycling order for the auth scheme.
+var authSchemes = []string{"none", "basic", "bearer", "apikey"}
+
+func nextAuthScheme(current string) string {
+	for i, s := range authSchemes {
+		if s == current {
+			return authSchemes[(i+1)%len(authSchemes)]
+		}
+	}
+	return authSchemes[0]
+}
+
+// headerEntry is a single user-supplied request header.
+type headerEntry struct {
+	key   string
+	value string
+}
+
 // Model definition and initialization
 type model struct {
-	text             string
-	urlInput         textinput.Model
-	methodInput      textinput.Model
-	focusedComponent component
-	quitting         bool
+	viewport          viewport.Model
+	lastFormatted     string
+	lastResult        FetchResult
+	debugMode         bool
+	urlInput          textinput.Model
+	methodInput       textinput.Model
+	headerInput       textinput.Model
+	bodyInput         textarea.Model
+	headers           []headerEntry
+	authScheme        string
+	authUserInput     textinput.Model
+	authSecretInput   textinput.Model
+	passphraseInput   textinput.Model
+	credentials       map[string]StoredCredential
+	credentialsLocked bool
+	collections       []Collection
+	collectionsList   list.Model
+	environments      []Environment
+	envList           list.Model
+	envEditorOpen     bool
+	envNameInput      textinput.Model
+	envVarInput       textinput.Model
+	envVars           map[string]string
+	activeEnvIndex    int
+	history           []FetchResult
+	historyCursor     int
+	focusedComponent  component
+	quitting          bool
+}
+
+// refreshDisplay syncs the viewport's content with the current display mode
+// (debug breakdown vs. pretty body) without re-fetching anything. The debug
+// breakdown only makes sense for a completed fetch, so with no such fetch to
+// show (e.g. after an error) it falls back to the same text the non-debug
+// view would show instead of a stale or placeholder debug panel.
+func (m *model) refreshDisplay() {
+	if m.debugMode && m.lastResult.Request != nil {
+		m.viewport.SetContent(renderDebugPanel(m.lastResult))
+	} else {
+		m.viewport.SetContent(m.lastFormatted)
+	}
+	m.viewport.GotoTop()
+}
+
+// setDisplay records new body text and refreshes the viewport.
+func (m *model) setDisplay(text string) {
+	m.lastFormatted = text
+	m.refreshDisplay()
+}
+
+// setError records error text, clearing any previous fetch result so the
+// debug panel doesn't keep showing a stale successful request's breakdown
+// once an error has occurred, then refreshes the viewport.
+func (m *model) setError(text string) {
+	m.lastResult = FetchResult{}
+	m.setDisplay(text)
 }
 
 func initialModel() model {
@@ -67,7 +172,232 @@ func initialModel() model {
 	method.Placeholder = "HTTP Method"
 	method.CharLimit = 6
 
-	return model{text: "", urlInput: url, methodInput: method, focusedComponent: textInputFocus}
+	header := textinput.New()
+	header.Placeholder = "Header: Value"
+	header.CharLimit = 256
+	header.Width = defaultWidth
+
+	body := textarea.New()
+	body.Placeholder = "Request body (sent for POST/PUT/PATCH)"
+	body.SetWidth(defaultWidth)
+	body.SetHeight(5)
+
+	authUser := textinput.New()
+	authUser.Placeholder = "Username / header name"
+	authUser.CharLimit = 256
+	authUser.Width = defaultWidth
+
+	authSecret := textinput.New()
+	authSecret.Placeholder = "Password / token / header value"
+	authSecret.CharLimit = 256
+	authSecret.Width = defaultWidth
+	authSecret.EchoMode = textinput.EchoPassword
+
+	passphrase := textinput.New()
+	passphrase.Placeholder = "Passphrase to encrypt saved credentials (optional)"
+	passphrase.CharLimit = 256
+	passphrase.Width = defaultWidth
+	passphrase.EchoMode = textinput.EchoPassword
+
+	// Load previously saved credentials, if any, assuming no passphrase. If
+	// the file is encrypted this fails and credentialsLocked is set so the
+	// user can unlock it later by entering the passphrase and pressing enter.
+	credentials, err := loadCredentials("")
+	credentialsLocked := err != nil
+	if credentialsLocked {
+		credentials = map[string]StoredCredential{}
+	}
+
+	// Load previously saved requests, if any, to prepopulate the list
+	collections, _ := loadCollections()
+
+	collectionsList := list.New(collectionItems(collections), list.NewDefaultDelegate(), defaultWidth, 8)
+	collectionsList.Title = "Saved Requests (ctrl+s save, enter load, d delete)"
+	collectionsList.SetShowHelp(false)
+
+	envName := textinput.New()
+	envName.Placeholder = "Environment name"
+	envName.CharLimit = 64
+	envName.Width = defaultWidth
+
+	envVar := textinput.New()
+	envVar.Placeholder = "NAME=value"
+	envVar.CharLimit = 256
+	envVar.Width = defaultWidth
+
+	// Load previously saved environments, if any, to prepopulate the list
+	environments, _ := loadEnvironments()
+
+	envList := list.New(environmentItems(environments), list.NewDefaultDelegate(), defaultWidth, 8)
+	envList.Title = "Saved Environments (ctrl+s save, enter activate/load, d delete)"
+	envList.SetShowHelp(false)
+
+	vp := viewport.New(defaultWidth*2, 12)
+
+	return model{
+		viewport:          vp,
+		urlInput:          url,
+		methodInput:       method,
+		headerInput:       header,
+		bodyInput:         body,
+		authScheme:        "none",
+		authUserInput:     authUser,
+		authSecretInput:   authSecret,
+		passphraseInput:   passphrase,
+		credentials:       credentials,
+		credentialsLocked: credentialsLocked,
+		collections:       collections,
+		collectionsList:   collectionsList,
+		environments:      environments,
+		envList:           envList,
+		envNameInput:      envName,
+		envVarInput:       envVar,
+		activeEnvIndex:    -1,
+		historyCursor:     -1,
+		focusedComponent:  textInputFocus,
+	}
+}
+
+// focusComponent blurs every input and focuses the one matching c.
+func (m model) focusComponent(c component) model {
+	m.urlInput.Blur()
+	m.methodInput.Blur()
+	m.headerInput.Blur()
+	m.bodyInput.Blur()
+	m.authUserInput.Blur()
+	m.authSecretInput.Blur()
+	m.passphraseInput.Blur()
+	m.envNameInput.Blur()
+	m.envVarInput.Blur()
+
+	switch c {
+	case textInputFocus:
+		m.urlInput.Focus()
+	case methodListFocus:
+		m.methodInput.Focus()
+	case headerFocus:
+		m.headerInput.Focus()
+	case bodyFocus:
+		m.bodyInput.Focus()
+	case authUserFocus:
+		m.authUserInput.Focus()
+	case authSecretFocus:
+		m.authSecretInput.Focus()
+	case passphraseFocus:
+		m.passphraseInput.Focus()
+	case envNameFocus:
+		m.envNameInput.Focus()
+	case envVarFocus:
+		m.envVarInput.Focus()
+	case collectionsFocus, viewportFocus, envListFocus:
+		// The list/viewport components capture input while focused; nothing to do.
+	}
+	m.focusedComponent = c
+	return m
+}
+
+// focusIndex returns the position of c within order.
+func focusIndex(order []component, c component) int {
+	for i, fc := range order {
+		if fc == c {
+			return i
+		}
+	}
+	return 0
+}
+
+// currentAuthenticator builds the Authenticator for the active scheme from
+// the live form inputs, falling back to a credential saved for the current
+// URL's host when the scheme is "none".
+func (m model) currentAuthenticator() Authenticator {
+	switch m.authScheme {
+	case "basic":
+		return BasicAuth{User: m.authUserInput.Value(), Pass: m.authSecretInput.Value()}
+	case "bearer":
+		return BearerAuth{Token: m.authSecretInput.Value()}
+	case "apikey":
+		return APIKeyAuth{Header: m.authUserInput.Value(), Value: m.authSecretInput.Value()}
+	default:
+		if cred, ok := m.credentials[hostFor(m.urlInput.Value())]; ok {
+			return authenticatorFromStored(cred)
+		}
+		return NoAuth{}
+	}
+}
+
+// storedCredential builds the StoredCredential the active scheme describes,
+// for persisting with ctrl+p.
+func (m model) storedCredential() StoredCredential {
+	switch m.authScheme {
+	case "basic":
+		return StoredCredential{Scheme: "basic", User: m.authUserInput.Value(), Pass: m.authSecretInput.Value()}
+	case "bearer":
+		return StoredCredential{Scheme: "bearer", Token: m.authSecretInput.Value()}
+	case "apikey":
+		return StoredCredential{Scheme: "apikey", Header: m.authUserInput.Value(), Value: m.authSecretInput.Value()}
+	default:
+		return StoredCredential{Scheme: "none"}
+	}
+}
+
+// activeEnvVars returns the variables of the currently active environment,
+// or nil if none is active.
+func (m model) activeEnvVars() map[string]string {
+	if m.activeEnvIndex < 0 || m.activeEnvIndex >= len(m.environments) {
+		return nil
+	}
+	return m.environments[m.activeEnvIndex].Vars
+}
+
+// activeEnvName returns the name of the active environment, for display.
+func (m model) activeEnvName() string {
+	if m.activeEnvIndex < 0 || m.activeEnvIndex >= len(m.environments) {
+		return "none"
+	}
+	return m.environments[m.activeEnvIndex].Name
+}
+
+// addEnvVarFromInput parses the "NAME=value" text in envVarInput and, if
+// valid, stores it in the in-progress envVars map and clears the input.
+func (m *model) addEnvVarFromInput() error {
+	raw := m.envVarInput.Value()
+	parts := strings.SplitN(raw, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected \"NAME=value\", got %q", raw)
+	}
+
+	name := strings.TrimSpace(parts[0])
+	value := strings.TrimSpace(parts[1])
+	if name == "" {
+		return fmt.Errorf("variable name cannot be empty")
+	}
+
+	if m.envVars == nil {
+		m.envVars = map[string]string{}
+	}
+	m.envVars[name] = value
+	m.envVarInput.SetValue("")
+	return nil
+}
+
+// addHeaderFromInput parses the "Key: Value" text in headerInput and, if
+// valid, appends it to m.headers and clears the input.
+func (m *model) addHeaderFromInput() error {
+	raw := m.headerInput.Value()
+	parts := strings.SplitN(raw, ":", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected \"Header: Value\", got %q", raw)
+	}
+
+	key := strings.TrimSpace(parts[0])
+	value := strings.TrimSpace(parts[1])
+	if key == "" {
+		return fmt.Errorf("header name cannot be empty")
+	}
+
+	m.headers = append(m.headers, headerEntry{key: key, value: value})
+	m.headerInput.SetValue("")
+	return nil
 }
 
 // Bubble Tea program functions
@@ -91,67 +421,313 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.quitting = true
 			return m, tea.Quit
 
-		case "enter":
-			// Validate URL
-			input := m.urlInput.Value()
-			if !isValidURL(input) {
-				m.text = `{ "error": "invalid URL, please try again" }`
+		case "ctrl+d":
+			m.debugMode = !m.debugMode
+			m.refreshDisplay()
+			return m, nil
+
+		case "ctrl+s":
+			if m.envEditorOpen {
+				env := Environment{Name: m.envNameInput.Value(), Vars: cloneVars(m.envVars)}
+				if env.Name == "" {
+					m.setError(formatJSONError("failed to save environment", "name cannot be empty"))
+					return m, nil
+				}
+				if err := saveEnvironment(env); err != nil {
+					m.setError(formatJSONError("failed to save environment", err.Error()))
+					return m, nil
+				}
+
+				replaced := false
+				for i, e := range m.environments {
+					if e.Name == env.Name {
+						m.environments[i] = env
+						m.activeEnvIndex = i
+						replaced = true
+						break
+					}
+				}
+				if !replaced {
+					m.environments = append(m.environments, env)
+					m.activeEnvIndex = len(m.environments) - 1
+				}
+				m.envList.SetItems(environmentItems(m.environments))
+				m.envVars = nil
+				m.envNameInput.SetValue("")
 				return m, nil
 			}
 
-			// Validate HTTP Method
-			method := strings.ToUpper(m.methodInput.Value())
-			if !httpMethods[method] {
-				m.text = `{ "error": "invalid HTTP method, please enter GET, POST, PUT, DELETE, or PATCH" }`
+			col := Collection{
+				Name:    strings.ToUpper(m.methodInput.Value()) + " " + m.urlInput.Value(),
+				URL:     m.urlInput.Value(),
+				Method:  strings.ToUpper(m.methodInput.Value()),
+				Headers: headersToMap(m.headers),
+				Body:    m.bodyInput.Value(),
+			}
+			m.collections = append(m.collections, col)
+			if err := saveCollections(m.collections); err != nil {
+				m.setError(formatJSONError("failed to save collection", err.Error()))
+			}
+			m.collectionsList.SetItems(collectionItems(m.collections))
+			return m, nil
+
+		case "ctrl+e":
+			m.envEditorOpen = !m.envEditorOpen
+			if m.envEditorOpen {
+				m.envVars = nil
+				m.envNameInput.SetValue("")
+				m = m.focusComponent(envNameFocus)
+			} else {
+				m = m.focusComponent(textInputFocus)
+			}
+			return m, nil
+
+		case "ctrl+a":
+			m.authScheme = nextAuthScheme(m.authScheme)
+			return m, nil
+
+		case "ctrl+p":
+			if m.credentialsLocked {
+				m.setError(formatJSONError("credentials are locked", "enter the passphrase in the auth panel and press enter to unlock before saving"))
 				return m, nil
 			}
+			host := hostFor(m.urlInput.Value())
+			if m.credentials == nil {
+				m.credentials = map[string]StoredCredential{}
+			}
+			m.credentials[host] = m.storedCredential()
+			if err := saveCredentials(m.credentials, m.passphraseInput.Value()); err != nil {
+				m.setError(formatJSONError("failed to save credentials", err.Error()))
+			}
+			return m, nil
 
-			// Fetch and format data with the validated method
-			data, err := FetchData(input, method)
-			if err != nil {
-				m.text = data
+		case "ctrl+r":
+			if len(m.history) == 0 {
+				return m, nil
+			}
+			if m.historyCursor <= 0 {
+				m.historyCursor = len(m.history) - 1
 			} else {
-				m.text = data
+				m.historyCursor--
 			}
+			entry := m.history[m.historyCursor]
+			m.lastResult = entry
+			m.setDisplay(entry.Formatted)
 			return m, nil
 
+		case "pgup", "pgdown":
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+
+		case "d":
+			if m.focusedComponent == collectionsFocus {
+				// Resolve the selection by identity rather than
+				// m.collectionsList.Index(), which is a position within the
+				// filtered view, not m.collections, and would delete the
+				// wrong entry while a filter is active.
+				if item, ok := m.collectionsList.SelectedItem().(collectionItem); ok {
+					for i, c := range m.collections {
+						if reflect.DeepEqual(c, item.Collection) {
+							m.collections = append(m.collections[:i], m.collections[i+1:]...)
+							break
+						}
+					}
+					if err := saveCollections(m.collections); err != nil {
+						m.setError(formatJSONError("failed to delete collection", err.Error()))
+					}
+					m.collectionsList.SetItems(collectionItems(m.collections))
+				}
+				return m, nil
+			}
+			if m.focusedComponent == envListFocus {
+				// Same identity-resolution concern as above: m.envList.Index()
+				// is a filtered-view position, not an index into m.environments.
+				if item, ok := m.envList.SelectedItem().(environmentItem); ok {
+					idx := -1
+					for i, e := range m.environments {
+						if e.Name == item.Name {
+							idx = i
+							break
+						}
+					}
+					if idx >= 0 {
+						m.environments = append(m.environments[:idx], m.environments[idx+1:]...)
+						if err := deleteEnvironment(item.Name); err != nil {
+							m.setError(formatJSONError("failed to delete environment", err.Error()))
+						}
+						m.envList.SetItems(environmentItems(m.environments))
+						if m.activeEnvIndex == idx {
+							m.activeEnvIndex = -1
+						} else if m.activeEnvIndex > idx {
+							m.activeEnvIndex--
+						}
+					}
+				}
+				return m, nil
+			}
+
+		case "enter":
+			switch m.focusedComponent {
+			case headerFocus:
+				if err := m.addHeaderFromInput(); err != nil {
+					m.setError(formatJSONError("invalid header", err.Error()))
+				}
+				return m, nil
+
+			case collectionsFocus:
+				if item, ok := m.collectionsList.SelectedItem().(collectionItem); ok {
+					m.urlInput.SetValue(item.URL)
+					m.methodInput.SetValue(item.Method)
+					m.bodyInput.SetValue(item.Body)
+					m.headers = headersFromMap(item.Headers)
+				}
+				return m, nil
+
+			case envVarFocus:
+				if err := m.addEnvVarFromInput(); err != nil {
+					m.setError(formatJSONError("invalid variable", err.Error()))
+				}
+				return m, nil
+
+			case passphraseFocus:
+				creds, err := loadCredentials(m.passphraseInput.Value())
+				if err != nil {
+					m.setError(formatJSONError("failed to decrypt credentials", err.Error()))
+					return m, nil
+				}
+				m.credentials = creds
+				m.credentialsLocked = false
+				return m, nil
+
+			case envListFocus:
+				if item, ok := m.envList.SelectedItem().(environmentItem); ok {
+					for i, e := range m.environments {
+						if e.Name == item.Name {
+							m.activeEnvIndex = i
+							break
+						}
+					}
+					m.envNameInput.SetValue(item.Name)
+					m.envVars = cloneVars(item.Vars)
+				}
+				return m, nil
+
+			case bodyFocus:
+				// Let the textarea insert a newline; fall through to the
+				// generic component update below.
+
+			default:
+				// Apply the active environment's {{NAME}} substitutions before
+				// validating, so a templated URL like {{BASE_URL}}/ping resolves
+				// first.
+				spec, err := templateRequest(RequestSpec{
+					URL:     m.urlInput.Value(),
+					Method:  strings.ToUpper(m.methodInput.Value()),
+					Headers: headersToMap(m.headers),
+					Body:    m.bodyInput.Value(),
+					Auth:    m.currentAuthenticator(),
+				}, m.activeEnvVars())
+				if err != nil {
+					m.setError(formatJSONError("unresolved template variable", err.Error()))
+					return m, nil
+				}
+
+				// Validate URL
+				if !isValidURL(spec.URL) {
+					m.setError(`{ "error": "invalid URL, please try again" }`)
+					return m, nil
+				}
+
+				// Validate HTTP Method
+				if !httpMethods[spec.Method] {
+					m.setError(`{ "error": "invalid HTTP method, please enter GET, POST, PUT, DELETE, or PATCH" }`)
+					return m, nil
+				}
+
+				// Fetch and format data with the validated method
+				result, err := FetchData(spec)
+				if err != nil {
+					m.setError(formatJSONError("request failed", err.Error()))
+					return m, nil
+				}
+
+				m.lastResult = result
+				m.history = append(m.history, result)
+				if len(m.history) > maxHistory {
+					m.history = m.history[len(m.history)-maxHistory:]
+				}
+				m.historyCursor = -1
+				m.setDisplay(result.Formatted)
+				return m, nil
+			}
+
 		case "down", "j":
-			if m.focusedComponent == textInputFocus {
-				m.focusedComponent = methodListFocus
-				m.urlInput.Blur()
-				m.methodInput.Focus()
+			if msg.String() == "j" || !arrowCyclesFocus(m.focusedComponent) {
+				break
+			}
+			order := focusOrder
+			if m.envEditorOpen {
+				order = envFocusOrder
+			}
+			next := focusIndex(order, m.focusedComponent) + 1
+			if next < len(order) {
+				m = m.focusComponent(order[next])
 			}
 			return m, nil
 
 		case "up", "k":
-			if m.focusedComponent == methodListFocus {
-				m.focusedComponent = textInputFocus
-				m.methodInput.Blur()
-				m.urlInput.Focus()
+			if msg.String() == "k" || !arrowCyclesFocus(m.focusedComponent) {
+				break
+			}
+			order := focusOrder
+			if m.envEditorOpen {
+				order = envFocusOrder
+			}
+			prev := focusIndex(order, m.focusedComponent) - 1
+			if prev >= 0 {
+				m = m.focusComponent(order[prev])
 			}
 			return m, nil
 
 		case "tab":
-			// Toggle focus between urlInput and methodInput on Tab key press
-			if m.focusedComponent == methodListFocus {
-				m.focusedComponent = textInputFocus
-				m.methodInput.Blur()
-				m.urlInput.Focus()
-			} else {
-				m.focusedComponent = methodListFocus
-				m.urlInput.Blur()
-				m.methodInput.Focus()
+			order := focusOrder
+			if m.envEditorOpen {
+				order = envFocusOrder
 			}
+			next := (focusIndex(order, m.focusedComponent) + 1) % len(order)
+			m = m.focusComponent(order[next])
 			return m, nil
 		}
 	}
 
 	var cmd tea.Cmd
 	// Update the input component based on which one is focused
-	if m.focusedComponent == textInputFocus {
+	switch m.focusedComponent {
+	case textInputFocus:
 		m.urlInput, cmd = m.urlInput.Update(msg)
-	} else {
+	case methodListFocus:
 		m.methodInput, cmd = m.methodInput.Update(msg)
+	case headerFocus:
+		m.headerInput, cmd = m.headerInput.Update(msg)
+	case bodyFocus:
+		m.bodyInput, cmd = m.bodyInput.Update(msg)
+	case authUserFocus:
+		m.authUserInput, cmd = m.authUserInput.Update(msg)
+	case authSecretFocus:
+		m.authSecretInput, cmd = m.authSecretInput.Update(msg)
+	case passphraseFocus:
+		m.passphraseInput, cmd = m.passphraseInput.Update(msg)
+	case envNameFocus:
+		m.envNameInput, cmd = m.envNameInput.Update(msg)
+	case envVarFocus:
+		m.envVarInput, cmd = m.envVarInput.Update(msg)
+	case envListFocus:
+		m.envList, cmd = m.envList.Update(msg)
+	case collectionsFocus:
+		m.collectionsList, cmd = m.collectionsList.Update(msg)
+	case viewportFocus:
+		m.viewport, cmd = m.viewport.Update(msg)
 	}
 
 	return m, cmd
@@ -162,208 +738,169 @@ func (m model) View() string {
 		return "Thanks for using go-forth!\n"
 	}
 
-	content := respStyle.Render(m.text)
-	return fmt.Sprintf(
-		"\nPlease enter a URL for a GET request:\n\n%s\n\n%s\n\n%s\n%s\n",
-		m.urlInput.View(),
-		m.methodInput.View(),
-		content,
-		"Press ctrl+c to exit",
-	)
-}
-
-func FetchData(url, method string) (string, error) {
-	// Create a new request with the selected method
-	req, err := http.NewRequest(method, url, nil)
-	if err != nil {
-		return formatJSONError("failed to create the request", err.Error()), nil
-	}
-
-	// Execute the request
-	resp, err := client.Do(req)
-	if err != nil {
-		return formatJSONError("failed to make the request", err.Error()), nil
+	if m.envEditorOpen {
+		return envEditorView(m)
 	}
-	defer resp.Body.Close()
 
-	// Check if the response status code is not 200 OK
-	if resp.StatusCode != http.StatusOK {
-		return formatJSONError("received non-200 response code", fmt.Sprintf("%d", resp.StatusCode)), nil
-	}
+	content := respStyle.Render(m.viewport.View())
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return formatJSONError("failed to read the response body", err.Error()), nil
+	var headerLines strings.Builder
+	for _, h := range m.headers {
+		headerLines.WriteString(fmt.Sprintf("%s: %s\n", h.key, h.value))
 	}
 
-	// Determine response format based on content type
-	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "application/json") && isJSON(body) {
-		// Attempt to pretty-print JSON
-		prettyJSON, err := prettyPrintJSON(string(body))
-		if err != nil {
-			return formatJSONError("error formatting JSON", err.Error()), nil
-		}
-		return prettyJSON, nil
+	credentialsStatus := ""
+	if m.credentialsLocked {
+		credentialsStatus = " [locked, enter passphrase + enter to unlock]"
 	}
 
-	// If not JSON, return as plain text with styling
-	return prettyPrintText(string(body)), nil
+	return fmt.Sprintf(
+		"\nPlease enter a URL and method:\n\n%s\n\n%s\n\nHeaders (enter to add, Tab to move on):\n%s%s\n\nBody:\n%s\n\nAuth (ctrl+a scheme: %s, ctrl+p save)%s:\n%s\n%s\n%s\n\n%s\n\nActive environment: %s (ctrl+e to edit)\n\n%s\n%s\n",
+		m.urlInput.View(),
+		m.methodInput.View(),
+		headerLines.String(),
+		m.headerInput.View(),
+		m.bodyInput.View(),
+		m.authScheme,
+		credentialsStatus,
+		m.authUserInput.View(),
+		m.authSecretInput.View(),
+		m.passphraseInput.View(),
+		m.collectionsList.View(),
+		m.activeEnvName(),
+		content,
+		"ctrl+c exit, ctrl+d debug, ctrl+s save, ctrl+a auth scheme, ctrl+p save credentials, ctrl+e environments, ctrl+r history, pgup/pgdn scroll",
+	)
 }
 
-// Helper functions for data validation and formatting
-func isValidURL(input string) bool {
-	parsedURL, err := url.ParseRequestURI(input)
-	return err == nil && parsedURL.Scheme != "" && parsedURL.Host != ""
+// envEditorView renders the environment editor opened with ctrl+e: the
+// in-progress name and variables, plus the list of previously saved
+// environments.
+func envEditorView(m model) string {
+	var varLines strings.Builder
+	for k, v := range m.envVars {
+		varLines.WriteString(fmt.Sprintf("%s=%s\n", k, v))
+	}
+
+	return fmt.Sprintf(
+		"\nEnvironment editor (ctrl+e close, ctrl+s save, enter on list to activate/load):\n\nName:\n%s\n\nVariables (enter to add, NAME=value):\n%s%s\n\n%s\n\nActive environment: %s\n",
+		m.envNameInput.View(),
+		varLines.String(),
+		m.envVarInput.View(),
+		m.envList.View(),
+		m.activeEnvName(),
+	)
 }
 
-func isJSON(data []byte) bool {
-	var js json.RawMessage
-	return json.Unmarshal(data, &js) == nil
+// RequestSpec describes an outgoing request before it is built and sent.
+type RequestSpec struct {
+	URL     string
+	Method  string
+	Headers map[string]string
+	Body    string
+	Auth    Authenticator
 }
 
-func formatJSONError(message, details string) string {
-	return fmt.Sprintf(`{ "error": "%s", "details": "%s" }`, message, details)
+// FetchResult carries everything needed to render either the pretty body or
+// the full request/response debug breakdown.
+type FetchResult struct {
+	Request     *http.Request
+	RequestBody string
+	Response    *http.Response
+	Elapsed     time.Duration
+	Body        []byte
+	Formatted   string
 }
 
-func truncateString(str string, length int) string {
-	if len(str) <= length {
-		return str
+func FetchData(spec RequestSpec) (FetchResult, error) {
+	// Build the request body for methods that carry one
+	var bodyReader io.Reader
+	var sentBody string
+	if spec.Body != "" && (spec.Method == http.MethodPost || spec.Method == http.MethodPut || spec.Method == http.MethodPatch) {
+		sentBody = spec.Body
+		bodyReader = bytes.NewReader([]byte(sentBody))
 	}
-	return str[:length] + "..."
-}
 
-func formatHTMLText(data string) (string, error) {
-	// Parse the HTML
-	node, err := html.Parse(strings.NewReader(data))
+	// Create a new request with the selected method
+	req, err := http.NewRequest(spec.Method, spec.URL, bodyReader)
 	if err != nil {
-		return "", err
+		return FetchResult{}, fmt.Errorf("failed to create the request: %w", err)
 	}
 
-	// Use a buffer to capture formatted output
-	var buf bytes.Buffer
-	formatNode(&buf, node, 0)
-	return buf.String(), nil
-}
-
-func formatNode(buf *bytes.Buffer, n *html.Node, level int) {
-	// Skip the root node and <head> element for formatting purposes
-	if n.Type == html.DocumentNode || (n.Type == html.ElementNode && n.Data == "head") {
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			formatNode(buf, c, level)
-		}
-		return
+	for key, value := range spec.Headers {
+		req.Header.Set(key, value)
 	}
 
-	// Check if the node has only one child and that child is a text node
-	if n.Type == html.ElementNode && n.FirstChild != nil && n.FirstChild == n.LastChild && n.FirstChild.Type == html.TextNode {
-		// Inline text content within tags
-		indent(buf, level)
-		buf.WriteString("<" + n.Data + ">")
-		buf.WriteString(strings.TrimSpace(n.FirstChild.Data)) // Inline text
-		buf.WriteString("</" + n.Data + ">\n")
-		return
+	if spec.Auth != nil {
+		spec.Auth.Apply(req)
 	}
 
-	// Add opening tag with indentation
-	if n.Type == html.ElementNode {
-		indent(buf, level)
-		buf.WriteString("<" + n.Data + ">\n")
+	// Execute the request, timing how long it takes
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("failed to make the request: %w", err)
 	}
+	defer resp.Body.Close()
+	elapsed := time.Since(start)
 
-	// Process child nodes
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		formatNode(buf, c, level+1)
+	// Read the response body regardless of status code, so callers can
+	// inspect non-200 responses instead of having them discarded
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("failed to read the response body: %w", err)
 	}
 
-	// Add closing tag for element nodes
-	if n.Type == html.ElementNode {
-		indent(buf, level)
-		buf.WriteString("</" + n.Data + ">\n")
-	} else if n.Type == html.TextNode {
-		// Add text content with indentation for multi-line text
-		text := strings.TrimSpace(n.Data)
-		if text != "" {
-			indent(buf, level)
-			buf.WriteString(text + "\n")
-		}
+	formatted, err := formatBody(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		formatted = formatJSONError("error formatting response", err.Error())
 	}
-}
 
-func indent(buf *bytes.Buffer, level int) {
-	buf.WriteString(strings.Repeat("  ", level))
+	return FetchResult{Request: req, RequestBody: sentBody, Response: resp, Elapsed: elapsed, Body: body, Formatted: formatted}, nil
 }
 
-func prettyPrintText(data string) string {
-	// Apply indentation using formatHTMLText, then style with lipgloss
-	formattedText, err := formatHTMLText(data)
-	if err != nil {
-		return formatJSONError("error formatting text", err.Error())
+// renderDebugPanel renders the outgoing request line/headers/body alongside
+// the response status line, headers, latency, and content length.
+func renderDebugPanel(r FetchResult) string {
+	if r.Request == nil || r.Response == nil {
+		return `{ "error": "no request has been sent yet" }`
 	}
 
-	return lipgloss.NewStyle().
-		Foreground(lipgloss.Color("250")).
-		Background(lipgloss.Color("235")).
-		Padding(1).
-		Margin(1).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("63")).
-		Render(truncateString(formattedText, 2000))
-}
+	var buf bytes.Buffer
 
-func prettyPrintJSON(data string) (string, error) {
-	if os.Getenv("TEST_MODE") == "true" {
-		// Skip pretty-printing during tests
-		return data, nil
+	buf.WriteString(keyStyle.Render("> "+r.Request.Method+" "+r.Request.URL.String()) + "\n")
+	for key, values := range r.Request.Header {
+		for _, v := range values {
+			buf.WriteString(keyStyle.Render("> "+key+": ") + v + "\n")
+		}
 	}
-
-	var jsonData interface{}
-	if err := json.Unmarshal([]byte(data), &jsonData); err != nil {
-		return "", fmt.Errorf(`{ "error": "invalid JSON format", "details": "%v" }`, err)
+	if r.RequestBody != "" {
+		buf.WriteString("\n" + r.RequestBody + "\n")
 	}
-	return renderJSON(jsonData, 0), nil
-}
 
-func renderJSON(data interface{}, level int) string {
-	var buf bytes.Buffer
-	indent := strings.Repeat(indentation, level)
-
-	switch v := data.(type) {
-	case map[string]interface{}:
-		buf.WriteString("{\n")
-		for key, value := range v {
-			buf.WriteString(indent + indentation)
-			buf.WriteString(keyStyle.Render(fmt.Sprintf(`"%s"`, key)) + ": ")
-			buf.WriteString(renderJSON(value, level+1))
-			buf.WriteString(",\n")
+	buf.WriteString("\n")
+	buf.WriteString(keyStyle.Render(fmt.Sprintf("< %s %s", r.Response.Proto, r.Response.Status)) + "\n")
+	for key, values := range r.Response.Header {
+		for _, v := range values {
+			buf.WriteString(keyStyle.Render("< "+key+": ") + v + "\n")
 		}
-		buf.Truncate(buf.Len() - 2)
-		buf.WriteString("\n" + indent + "}")
-
-	case []interface{}:
-		buf.WriteString("[\n")
-		for _, item := range v {
-			buf.WriteString(indent + indentation)
-			buf.WriteString(renderJSON(item, level+1))
-			buf.WriteString(",\n")
-		}
-		buf.Truncate(buf.Len() - 2)
-		buf.WriteString("\n" + indent + "]")
-
-	case string:
-		buf.WriteString(stringStyle.Render(fmt.Sprintf(`"%s"`, v)))
-	case float64:
-		buf.WriteString(numberStyle.Render(fmt.Sprintf("%v", v)))
-	case bool:
-		buf.WriteString(boolStyle.Render(fmt.Sprintf("%v", v)))
-	case nil:
-		buf.WriteString(nullStyle.Render("null"))
 	}
+	buf.WriteString(numberStyle.Render(fmt.Sprintf("latency: %s", r.Elapsed)) + "\n")
+	buf.WriteString(numberStyle.Render(fmt.Sprintf("content length: %d bytes", len(r.Body))) + "\n")
 
 	return buf.String()
 }
 
+// Helper functions for data validation and formatting
+func isValidURL(input string) bool {
+	parsedURL, err := url.ParseRequestURI(input)
+	return err == nil && parsedURL.Scheme != "" && parsedURL.Host != ""
+}
+
+func formatJSONError(message, details string) string {
+	return fmt.Sprintf(`{ "error": "%s", "details": "%s" }`, message, details)
+}
+
 func main() {
 	p := tea.NewProgram(initialModel())
 	_, err := p.Run()
@@ -2,13 +2,17 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test helper for checking valid and invalid URLs
@@ -53,14 +57,14 @@ func TestFetchData(t *testing.T) {
 	defer server.Close()
 
 	// JSON Response
-	resp, err := FetchData(server.URL+"/json", "GET")
+	result, err := FetchData(RequestSpec{URL: server.URL + "/json", Method: "GET"})
 	assert.NoError(t, err)
-	assert.Contains(t, resp, `"message": "Hello, JSON!"`)
+	assert.Contains(t, result.Formatted, `"message": "Hello, JSON!"`)
 
 	// Plain Text Response
-	resp, err = FetchData(server.URL+"/plain", "GET")
+	result, err = FetchData(RequestSpec{URL: server.URL + "/plain", Method: "GET"})
 	assert.NoError(t, err)
-	assert.Contains(t, resp, "Hello, plain text!")
+	assert.Contains(t, result.Formatted, "Hello, plain text!")
 }
 
 func TestPrettyPrintText(t *testing.T) {
@@ -89,18 +93,280 @@ func TestUpdateFunction(t *testing.T) {
 	msg := tea.KeyMsg{Type: tea.KeyEnter}
 	updatedModel, _ := m.Update(msg)
 	m = updatedModel.(model)
-	assert.NotContains(t, m.text, "error", "Expected no error in valid input")
+	assert.NotContains(t, m.lastFormatted, "error", "Expected no error in valid input")
 
 	// Test invalid URL entry
 	m.urlInput.SetValue("not-a-url")
 	updatedModel, _ = m.Update(msg)
 	m = updatedModel.(model)
-	assert.Contains(t, m.text, "invalid URL", "Expected invalid URL error")
+	assert.Contains(t, m.lastFormatted, "invalid URL", "Expected invalid URL error")
 
 	// Test invalid HTTP method
 	m.urlInput.SetValue("https://example.com")
 	m.methodInput.SetValue("FETCH")
 	updatedModel, _ = m.Update(msg)
 	m = updatedModel.(model)
-	assert.Contains(t, m.text, "invalid HTTP method", "Expected invalid HTTP method error")
+	assert.Contains(t, m.lastFormatted, "invalid HTTP method", "Expected invalid HTTP method error")
+}
+
+// Test that literal "j"/"k" keystrokes type into the URL field instead of
+// being swallowed as a focus-cycling shortcut.
+func TestLiteralJKReachTextInputs(t *testing.T) {
+	m := initialModel()
+	m.urlInput.SetValue("")
+
+	for _, r := range "jsonplaceholder.typicode.com/k" {
+		updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updatedModel.(model)
+	}
+
+	assert.Equal(t, "jsonplaceholder.typicode.com/k", m.urlInput.Value())
+	assert.Equal(t, textInputFocus, m.focusedComponent, "focus should stay on the URL field")
+}
+
+// Test that each auth scheme sets the header an API actually expects.
+func TestAuthApply(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.com", nil)
+	assert.NoError(t, err)
+	BasicAuth{User: "alice", Pass: "hunter2"}.Apply(req)
+	user, pass, ok := req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "alice", user)
+	assert.Equal(t, "hunter2", pass)
+
+	req, err = http.NewRequest("GET", "https://example.com", nil)
+	assert.NoError(t, err)
+	BearerAuth{Token: "abc123"}.Apply(req)
+	assert.Equal(t, "Bearer abc123", req.Header.Get("Authorization"))
+
+	req, err = http.NewRequest("GET", "https://example.com", nil)
+	assert.NoError(t, err)
+	APIKeyAuth{Header: "X-API-Key", Value: "secret"}.Apply(req)
+	assert.Equal(t, "secret", req.Header.Get("X-API-Key"))
+
+	req, err = http.NewRequest("GET", "https://example.com", nil)
+	assert.NoError(t, err)
+	NoAuth{}.Apply(req)
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+// Test that saved credentials encrypted with a passphrase can be reloaded
+// with the same passphrase, and reject a wrong one.
+func TestCredentialsEncryptRoundTrip(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	creds := map[string]StoredCredential{
+		"api.example.com": {Scheme: "bearer", Token: "secret-token"},
+	}
+	assert.NoError(t, saveCredentials(creds, "hunter2"))
+
+	loaded, err := loadCredentials("hunter2")
+	assert.NoError(t, err)
+	assert.Equal(t, creds, loaded)
+
+	_, err = loadCredentials("wrong-passphrase")
+	assert.Error(t, err)
+}
+
+// Test {{var}} substitution and the unresolved-placeholder error path.
+func TestApplyTemplate(t *testing.T) {
+	vars := map[string]string{"BASE_URL": "https://api.example.com", "TOKEN": "abc123"}
+	assert.Equal(t, "https://api.example.com/users", applyTemplate("{{BASE_URL}}/users", vars))
+	assert.Equal(t, "Bearer abc123", applyTemplate("Bearer {{TOKEN}}", vars))
+}
+
+func TestTemplateRequestUnresolved(t *testing.T) {
+	spec := RequestSpec{URL: "{{BASE_URL}}/users", Method: "GET"}
+	_, err := templateRequest(spec, map[string]string{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "BASE_URL")
+}
+
+// Test that saved collections round-trip through disk and that deleting
+// down to an empty slice clears the file.
+func TestCollectionsSaveLoadDelete(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cols := []Collection{
+		{Name: "ping", URL: "https://example.com/ping", Method: "GET", Headers: map[string]string{"Accept": "application/json"}},
+	}
+	assert.NoError(t, saveCollections(cols))
+
+	loaded, err := loadCollections()
+	assert.NoError(t, err)
+	assert.Equal(t, cols, loaded)
+
+	assert.NoError(t, saveCollections(nil))
+	loaded, err = loadCollections()
+	assert.NoError(t, err)
+	assert.Empty(t, loaded)
+}
+
+// Test that the in-memory history ring is capped at maxHistory entries and
+// that ctrl+r walks backwards through it, wrapping from oldest to newest.
+func TestHistoryRingAndRecall(t *testing.T) {
+	m := initialModel()
+	for i := 0; i < maxHistory+5; i++ {
+		m.history = append(m.history, FetchResult{Formatted: fmt.Sprintf("response %d", i)})
+		if len(m.history) > maxHistory {
+			m.history = m.history[len(m.history)-maxHistory:]
+		}
+	}
+	assert.Len(t, m.history, maxHistory)
+	assert.Equal(t, "response 5", m.history[0].Formatted)
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updatedModel.(model)
+	assert.Equal(t, "response "+fmt.Sprint(maxHistory+4), m.lastFormatted)
+
+	for i := 0; i < maxHistory-1; i++ {
+		updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+		m = updatedModel.(model)
+	}
+	assert.Equal(t, "response 5", m.lastFormatted, "cursor should have walked back to the oldest entry")
+
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updatedModel.(model)
+	assert.Equal(t, "response "+fmt.Sprint(maxHistory+4), m.lastFormatted, "ctrl+r should wrap back around to the newest entry")
+}
+
+// Test that a crafted environment name can't escape the envs directory.
+func TestSaveEnvironmentRejectsTraversal(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	escapeDir := t.TempDir()
+	name := filepath.Join(strings.Repeat("../", 10), escapeDir, "zz_escaped_env_poc")
+
+	err := saveEnvironment(Environment{Name: name})
+	assert.Error(t, err)
+
+	_, statErr := os.Stat(filepath.Join(escapeDir, "zz_escaped_env_poc.json"))
+	assert.True(t, os.IsNotExist(statErr))
+
+	assert.Error(t, deleteEnvironment(name))
+}
+
+// Test that editing vars after saving one environment can't mutate a
+// previously saved or loaded environment's Vars map in place.
+func TestEnvVarsNotAliasedAcrossSaves(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	m := initialModel()
+	m.envEditorOpen = true
+	m.envNameInput.SetValue("envA")
+	m.envVarInput.SetValue("KEY=one")
+	require.NoError(t, m.addEnvVarFromInput())
+
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updatedModel.(model)
+	require.Len(t, m.environments, 1)
+
+	m.envEditorOpen = true
+	m.envNameInput.SetValue("envB")
+	m.envVarInput.SetValue("KEY=two")
+	require.NoError(t, m.addEnvVarFromInput())
+
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updatedModel.(model)
+	require.Len(t, m.environments, 2)
+
+	m.envList.SetItems(environmentItems(m.environments))
+	for i, e := range m.environments {
+		if e.Name == "envA" {
+			m.envList.Select(i)
+		}
+	}
+	m.focusedComponent = envListFocus
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+
+	m.envVarInput.SetValue("KEY=mutated")
+	require.NoError(t, m.addEnvVarFromInput())
+
+	var envA, envB Environment
+	for _, e := range m.environments {
+		switch e.Name {
+		case "envA":
+			envA = e
+		case "envB":
+			envB = e
+		}
+	}
+	assert.Equal(t, "one", envA.Vars["KEY"], "editing the reloaded envA's vars must not touch the saved copy")
+	assert.Equal(t, "two", envB.Vars["KEY"], "editing envA's vars must not leak into envB")
+}
+
+// Test that debug mode shows the current error/status text instead of a
+// stale or placeholder debug panel when there's no completed fetch to show a
+// breakdown for.
+func TestDebugModeShowsCurrentError(t *testing.T) {
+	m := initialModel()
+	m.debugMode = true
+	m.focusedComponent = headerFocus
+
+	m.headerInput.SetValue("not-a-valid-header")
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+
+	assert.Contains(t, m.viewport.View(), "invalid header", "debug mode must surface the error, not a stale/placeholder debug panel")
+}
+
+// drainCmd feeds the message produced by cmd (recursing into any
+// tea.BatchMsg) back into m.Update, emulating one tick of the event loop a
+// real tea.Program runs. It doesn't chase whatever further cmd that Update
+// call returns, since components like the filter input's cursor blink
+// forever by design and would never let a test converge. Test helper for
+// driving components, like the list's filter editor, whose updates are only
+// half-applied until their returned commands are executed.
+func drainCmd(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+	if cmd == nil {
+		return m
+	}
+	msg := cmd()
+	if msg == nil {
+		return m
+	}
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			m = drainCmd(t, m, c)
+		}
+		return m
+	}
+	updatedModel, _ := m.Update(msg)
+	return updatedModel.(model)
+}
+
+// Test that pressing d on a collections list filtered down to one entry
+// deletes that entry, not whatever entry happens to sit at the filtered
+// cursor's position in the unfiltered m.collections slice.
+func TestDeleteCollectionWhileFiltered(t *testing.T) {
+	m := initialModel()
+	m.collections = []Collection{
+		{Name: "alpha-ping", URL: "http://alpha", Method: "GET"},
+		{Name: "zzz-only-me", URL: "http://zzz", Method: "GET"},
+	}
+	m.collectionsList.SetItems(collectionItems(m.collections))
+	m.focusedComponent = collectionsFocus
+
+	updatedModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = updatedModel.(model)
+	m = drainCmd(t, m, cmd)
+
+	for _, r := range "only" {
+		updatedModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updatedModel.(model)
+		m = drainCmd(t, m, cmd)
+	}
+
+	require.Len(t, m.collectionsList.VisibleItems(), 1, "filter should have narrowed the list down to the one matching entry")
+
+	updatedModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = updatedModel.(model)
+	m = drainCmd(t, m, cmd)
+
+	require.Len(t, m.collections, 1)
+	assert.Equal(t, "alpha-ping", m.collections[0].Name, "deleting while filtered must remove the filtered-to entry, not an unrelated one")
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// unresolvedPlaceholder matches any {{...}} left after substitution.
+var unresolvedPlaceholder = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// applyTemplate replaces {{NAME}} placeholders in s with values from vars.
+func applyTemplate(s string, vars map[string]string) string {
+	if len(vars) == 0 {
+		return s
+	}
+
+	oldnew := make([]string, 0, len(vars)*2)
+	for k, v := range vars {
+		oldnew = append(oldnew, "{{"+k+"}}", v)
+	}
+	return strings.NewReplacer(oldnew...).Replace(s)
+}
+
+// templateRequest substitutes vars into spec's URL, headers, and body,
+// returning an error if any {{...}} placeholder remains unresolved.
+func templateRequest(spec RequestSpec, vars map[string]string) (RequestSpec, error) {
+	spec.URL = applyTemplate(spec.URL, vars)
+	spec.Body = applyTemplate(spec.Body, vars)
+
+	headers := make(map[string]string, len(spec.Headers))
+	for k, v := range spec.Headers {
+		headers[k] = applyTemplate(v, vars)
+	}
+	spec.Headers = headers
+
+	if m := unresolvedPlaceholder.FindString(spec.URL); m != "" {
+		return spec, fmt.Errorf("unresolved template variable %s in URL", m)
+	}
+	if m := unresolvedPlaceholder.FindString(spec.Body); m != "" {
+		return spec, fmt.Errorf("unresolved template variable %s in body", m)
+	}
+	for k, v := range spec.Headers {
+		if m := unresolvedPlaceholder.FindString(v); m != "" {
+			return spec, fmt.Errorf("unresolved template variable %s in header %q", m, k)
+		}
+	}
+
+	return spec, nil
+}